@@ -0,0 +1,146 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// runAdminServer serves the feed-management API: GET/POST/DELETE /feeds
+// (DELETE takes the feed URL as a ?url= query param, since feed URLs
+// contain "://" and "//" and don't survive as a ServeMux path segment),
+// POST /feeds/import (OPML upload) and GET /feeds/export.opml. It's bound
+// to admin.Addr, localhost by default, and every route requires HTTP basic
+// auth.
+func runAdminServer(admin AdminConfig) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feeds", requireAdminAuth(admin, handleFeedsCollection))
+	mux.HandleFunc("/feeds/import", requireAdminAuth(admin, handleFeedsImport))
+	mux.HandleFunc("/feeds/export.opml", requireAdminAuth(admin, handleFeedsExport))
+
+	log.Printf("Admin API listening on %s", admin.Addr)
+	if err := http.ListenAndServe(admin.Addr, mux); err != nil {
+		log.Printf("Admin API server stopped: %v", err)
+	}
+}
+
+func requireAdminAuth(admin AdminConfig, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(admin.Username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(admin.Password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="rss_email admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func handleFeedsCollection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		feeds, err := ListFeeds()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, feeds)
+	case http.MethodPost:
+		var feed FeedConfig
+		if err := json.NewDecoder(r.Body).Decode(&feed); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if feed.URL == "" {
+			http.Error(w, "url is required", http.StatusBadRequest)
+			return
+		}
+		if err := AddFeed(feed); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodDelete:
+		feedURL := r.URL.Query().Get("url")
+		if feedURL == "" {
+			http.Error(w, "url query param is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := RemoveFeed(feedURL); err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "feed not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func handleFeedsImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	feeds, err := ParseOPML(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid OPML: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, feed := range feeds {
+		if err := AddFeed(feed); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeJSON(w, map[string]int{"imported": len(feeds)})
+}
+
+func handleFeedsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	feeds, err := ListFeeds()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := ExportOPML(feeds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml+xml")
+	w.Write(data)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error encoding JSON response: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	readability "github.com/go-shiori/go-readability"
+	"github.com/temoto/robotstxt"
+)
+
+const defaultArticleUserAgent = "rss_email/1.0 (Feed Reader)"
+
+// FetchFullArticle downloads link and runs readability extraction on it,
+// honoring robots.txt for the given userAgent.
+func FetchFullArticle(link, userAgent string) (string, error) {
+	if userAgent == "" {
+		userAgent = defaultArticleUserAgent
+	}
+
+	if allowed, err := robotsAllow(link, userAgent); err == nil && !allowed {
+		return "", fmt.Errorf("robots.txt disallows fetching %s", link)
+	}
+
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse article URL: %w", err)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	req, err := http.NewRequest("GET", link, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch article: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	article, err := readability.FromReader(resp.Body, parsedURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract readable content: %w", err)
+	}
+
+	return article.Content, nil
+}
+
+// robotsAllow checks link against its host's robots.txt. It fails open
+// (allowed=true) when robots.txt can't be fetched or parsed, since a missing
+// robots.txt conventionally means "everything is allowed".
+func robotsAllow(link, userAgent string) (bool, error) {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return true, fmt.Errorf("failed to parse article URL: %w", err)
+	}
+
+	robotsURL := fmt.Sprintf("%s://%s/robots.txt", parsedURL.Scheme, parsedURL.Host)
+	resp, err := http.Get(robotsURL)
+	if err != nil {
+		return true, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := robotstxt.FromResponse(resp)
+	if err != nil {
+		return true, fmt.Errorf("failed to parse robots.txt: %w", err)
+	}
+
+	return data.TestAgent(parsedURL.Path, userAgent), nil
+}
@@ -8,13 +8,102 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+const defaultFolderTemplate = "RSS/{feedName}"
+
+const (
+	immediateMode = "immediate"
+	digestMode    = "digest"
+)
+
+const defaultDigestSchedule = "0 8 * * *" // daily at 08:00
+
+const defaultAdminAddr = "127.0.0.1:8081"
+
+const defaultMetricsAddr = "127.0.0.1:9090"
+
+const defaultPollConcurrency = 8
+
+// AdminConfig holds the settings for the optional feed-management HTTP API.
+// It's bound to localhost by default since it carries no TLS of its own.
+type AdminConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Addr     string `yaml:"addr"`
+	Username string
+	Password string
+}
+
+// MetricsConfig holds the settings for the optional Prometheus /metrics
+// endpoint. Like the admin API, it's bound to localhost by default.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`
+}
+
+// IMAPConfig holds the settings for the "imap" delivery backend: items are
+// APPENDed to a per-feed folder instead of being mailed out.
+type IMAPConfig struct {
+	Server         string `yaml:"server"`
+	Port           string `yaml:"port"`
+	TLS            bool   `yaml:"tls"`
+	FolderTemplate string `yaml:"folder_template"`
+	Username       string
+	Password       string
+}
+
+// FeedConfig describes a single subscribed feed. It unmarshals from either
+// a bare URL string (the common case) or a mapping when a feed needs its own
+// settings (mode, fetch_full_content, user_agent, filters). The subscribed feed list itself lives
+// in the feeds table; config.yaml's feeds only seed it on first run.
+type FeedConfig struct {
+	URL              string       `yaml:"url"`
+	Mode             string       `yaml:"mode"`
+	FetchFullContent bool         `yaml:"fetch_full_content"`
+	UserAgent        string       `yaml:"user_agent"`
+	Filters          FilterConfig `yaml:"filters"`
+}
+
+func (f *FeedConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var url string
+	if err := unmarshal(&url); err == nil {
+		f.URL = url
+		return nil
+	}
+
+	type rawFeedConfig FeedConfig
+	var raw rawFeedConfig
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	*f = FeedConfig(raw)
+	return nil
+}
+
 type Config struct {
-	Feeds            []string `yaml:"feeds"`
+	Feeds            []FeedConfig  `yaml:"feeds"`
+	Mode             string        `yaml:"mode"`
+	DigestSchedule   string        `yaml:"digest_schedule"`
+	Delivery         string        `yaml:"delivery"`
+	IMAP             IMAPConfig    `yaml:"imap"`
+	Admin            AdminConfig   `yaml:"admin"`
+	Metrics          MetricsConfig `yaml:"metrics"`
+	PollConcurrency  int           `yaml:"poll_concurrency"`
 	GmailAddress     string
 	GmailAppPassword string
 	RecipientEmail   string
 }
 
+// ResolveMode returns the effective delivery mode for a feed: its own
+// override if set, else the configured global default, else immediate.
+func (cfg *Config) ResolveMode(feed FeedConfig) string {
+	if feed.Mode != "" {
+		return feed.Mode
+	}
+	if cfg.Mode != "" {
+		return cfg.Mode
+	}
+	return immediateMode
+}
+
 func Load(configPath string) (*Config, error) {
 	_ = godotenv.Load()
 
@@ -28,23 +117,98 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	if cfg.Delivery == "" {
+		cfg.Delivery = "smtp"
+	}
+
+	if cfg.DigestSchedule == "" {
+		cfg.DigestSchedule = defaultDigestSchedule
+	}
+
+	if cfg.PollConcurrency <= 0 {
+		cfg.PollConcurrency = defaultPollConcurrency
+	}
+
+	switch cfg.Delivery {
+	case "smtp":
+		if err := cfg.loadSMTPEnv(); err != nil {
+			return nil, err
+		}
+	case "imap":
+		if err := cfg.loadIMAPEnv(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown delivery backend %q: must be smtp or imap", cfg.Delivery)
+	}
+
+	if cfg.Admin.Enabled {
+		if err := cfg.loadAdminEnv(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Metrics.Enabled && cfg.Metrics.Addr == "" {
+		cfg.Metrics.Addr = defaultMetricsAddr
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *Config) loadSMTPEnv() error {
 	cfg.GmailAddress = os.Getenv("GMAIL_ADDRESS")
 	cfg.GmailAppPassword = os.Getenv("GMAIL_APP_PASSWORD")
 	cfg.RecipientEmail = os.Getenv("RECIPIENT_EMAIL")
 
 	if cfg.GmailAddress == "" {
-		return nil, fmt.Errorf("GMAIL_ADDRESS environment variable is required")
+		return fmt.Errorf("GMAIL_ADDRESS environment variable is required")
 	}
 	if cfg.GmailAppPassword == "" {
-		return nil, fmt.Errorf("GMAIL_APP_PASSWORD environment variable is required")
+		return fmt.Errorf("GMAIL_APP_PASSWORD environment variable is required")
 	}
 	if cfg.RecipientEmail == "" {
-		return nil, fmt.Errorf("RECIPIENT_EMAIL environment variable is required")
+		return fmt.Errorf("RECIPIENT_EMAIL environment variable is required")
 	}
 
-	if len(cfg.Feeds) == 0 {
-		return nil, fmt.Errorf("no feeds configured in config.yaml")
+	return nil
+}
+
+func (cfg *Config) loadIMAPEnv() error {
+	cfg.IMAP.Username = os.Getenv("IMAP_USERNAME")
+	cfg.IMAP.Password = os.Getenv("IMAP_PASSWORD")
+
+	if cfg.IMAP.Username == "" {
+		return fmt.Errorf("IMAP_USERNAME environment variable is required")
+	}
+	if cfg.IMAP.Password == "" {
+		return fmt.Errorf("IMAP_PASSWORD environment variable is required")
+	}
+	if cfg.IMAP.Server == "" {
+		return fmt.Errorf("imap.server is required in config.yaml")
+	}
+	if cfg.IMAP.Port == "" {
+		return fmt.Errorf("imap.port is required in config.yaml")
+	}
+	if cfg.IMAP.FolderTemplate == "" {
+		cfg.IMAP.FolderTemplate = defaultFolderTemplate
 	}
 
-	return &cfg, nil
+	return nil
+}
+
+func (cfg *Config) loadAdminEnv() error {
+	if cfg.Admin.Addr == "" {
+		cfg.Admin.Addr = defaultAdminAddr
+	}
+	cfg.Admin.Username = os.Getenv("ADMIN_USERNAME")
+	cfg.Admin.Password = os.Getenv("ADMIN_PASSWORD")
+
+	if cfg.Admin.Username == "" {
+		return fmt.Errorf("ADMIN_USERNAME environment variable is required when admin.enabled is true")
+	}
+	if cfg.Admin.Password == "" {
+		return fmt.Errorf("ADMIN_PASSWORD environment variable is required when admin.enabled is true")
+	}
+
+	return nil
 }
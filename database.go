@@ -1,7 +1,9 @@
 package main
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -58,9 +60,10 @@ func Initialize(dbPath string) error {
 	// We ignore errors here as columns might already exist
 	_, _ = db.Exec("ALTER TABLE feed_metadata ADD COLUMN next_check_after TIMESTAMP")
 	_, _ = db.Exec("ALTER TABLE feed_metadata ADD COLUMN error_count INTEGER DEFAULT 0")
+	_, _ = db.Exec("ALTER TABLE sent_items ADD COLUMN content_hash TEXT")
 
 	createIndexSQL := `
-	CREATE INDEX IF NOT EXISTS idx_feed_guid 
+	CREATE INDEX IF NOT EXISTS idx_feed_guid
 	ON sent_items(feed_url, item_guid);
 	`
 
@@ -68,31 +71,197 @@ func Initialize(dbPath string) error {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
+	createPendingDigestTableSQL := `
+	CREATE TABLE IF NOT EXISTS pending_digest (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_url TEXT NOT NULL,
+		feed_name TEXT NOT NULL,
+		item_guid TEXT NOT NULL,
+		title TEXT NOT NULL,
+		link TEXT NOT NULL,
+		published TEXT,
+		published_at TIMESTAMP,
+		summary TEXT,
+		created_at TIMESTAMP NOT NULL,
+		UNIQUE(feed_url, item_guid)
+	);
+	`
+
+	if _, err := db.Exec(createPendingDigestTableSQL); err != nil {
+		return fmt.Errorf("failed to create pending_digest table: %w", err)
+	}
+
+	createFeedsTableSQL := `
+	CREATE TABLE IF NOT EXISTS feeds (
+		url TEXT PRIMARY KEY,
+		mode TEXT NOT NULL DEFAULT '',
+		fetch_full_content INTEGER NOT NULL DEFAULT 0,
+		user_agent TEXT NOT NULL DEFAULT '',
+		filters_json TEXT NOT NULL DEFAULT ''
+	);
+	`
+
+	if _, err := db.Exec(createFeedsTableSQL); err != nil {
+		return fmt.Errorf("failed to create feeds table: %w", err)
+	}
+
+	_, _ = db.Exec("ALTER TABLE feeds ADD COLUMN filters_json TEXT NOT NULL DEFAULT ''")
+
+	createArticleCacheTableSQL := `
+	CREATE TABLE IF NOT EXISTS article_cache (
+		item_guid TEXT PRIMARY KEY,
+		content TEXT NOT NULL,
+		fetched_at TIMESTAMP NOT NULL
+	);
+	`
+
+	if _, err := db.Exec(createArticleCacheTableSQL); err != nil {
+		return fmt.Errorf("failed to create article_cache table: %w", err)
+	}
+
 	return nil
 }
 
-func IsItemSent(feedURL, itemGUID string) (bool, error) {
-	var count int
-	query := "SELECT COUNT(*) FROM sent_items WHERE feed_url = ? AND item_guid = ?"
-	err := db.QueryRow(query, feedURL, itemGUID).Scan(&count)
+// IsItemSent reports whether an item with the given GUID has already been
+// sent for this feed, and whether the content hash we have on file for it
+// differs from contentHash. A changed hash means the feed reused the GUID
+// for an edited post and the item should be re-sent as an update. Rows
+// recorded before content_hash existed have no hash and never report changed.
+func IsItemSent(feedURL, itemGUID, contentHash string) (sent bool, changed bool, err error) {
+	var storedHash string
+	query := "SELECT COALESCE(content_hash, '') FROM sent_items WHERE feed_url = ? AND item_guid = ?"
+	err = db.QueryRow(query, feedURL, itemGUID).Scan(&storedHash)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
 	if err != nil {
-		return false, fmt.Errorf("failed to check if item is sent: %w", err)
+		return false, false, fmt.Errorf("failed to check if item is sent: %w", err)
 	}
-	return count > 0, nil
+	return true, storedHash != "" && storedHash != contentHash, nil
 }
 
-func MarkItemSent(feedURL, itemGUID string) error {
-	query := "INSERT INTO sent_items (feed_url, item_guid, sent_at) VALUES (?, ?, ?)"
-	_, err := db.Exec(query, feedURL, itemGUID, time.Now())
+func MarkItemSent(feedURL, itemGUID, contentHash string) error {
+	query := `INSERT INTO sent_items (feed_url, item_guid, content_hash, sent_at)
+	          VALUES (?, ?, ?, ?)
+	          ON CONFLICT(feed_url, item_guid) DO UPDATE SET
+	            content_hash = excluded.content_hash,
+	            sent_at = excluded.sent_at`
+	_, err := db.Exec(query, feedURL, itemGUID, contentHash, time.Now())
 	if err != nil {
-		if err.Error() == "UNIQUE constraint failed: sent_items.feed_url, sent_items.item_guid" {
-			return nil
-		}
 		return fmt.Errorf("failed to mark item as sent: %w", err)
 	}
 	return nil
 }
 
+// ContentHash derives a stable fingerprint for a feed item from its title,
+// link and summary, so edited posts that keep the same GUID can be detected.
+func ContentHash(item FeedItem) string {
+	sum := sha256.Sum256([]byte(item.Title + item.Link + item.Summary))
+	return hex.EncodeToString(sum[:])
+}
+
+// DigestItem is a row queued in pending_digest, awaiting the next digest run.
+type DigestItem struct {
+	ID          int64
+	FeedURL     string
+	FeedName    string
+	GUID        string
+	Title       string
+	Link        string
+	Published   string
+	PublishedAt *time.Time
+	Summary     string
+}
+
+func QueueDigestItem(feedURL, feedName string, item FeedItem) error {
+	query := `INSERT INTO pending_digest (feed_url, feed_name, item_guid, title, link, published, published_at, summary, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	          ON CONFLICT(feed_url, item_guid) DO NOTHING`
+	_, err := db.Exec(query, feedURL, feedName, item.GUID, item.Title, item.Link, item.Published, item.PublishedDT, item.Summary, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to queue digest item: %w", err)
+	}
+	return nil
+}
+
+func GetPendingDigestItems() ([]DigestItem, error) {
+	query := `SELECT id, feed_url, feed_name, item_guid, title, link, COALESCE(published, ''), published_at, COALESCE(summary, '')
+	          FROM pending_digest ORDER BY feed_name, published_at`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending digest items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []DigestItem
+	for rows.Next() {
+		var item DigestItem
+		if err := rows.Scan(&item.ID, &item.FeedURL, &item.FeedName, &item.GUID, &item.Title, &item.Link, &item.Published, &item.PublishedAt, &item.Summary); err != nil {
+			return nil, fmt.Errorf("failed to scan pending digest item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// ClearPendingDigestItems removes the given rows from pending_digest. It is
+// called right after a digest email has been delivered; nothing is lost if
+// the process crashes before that, but a crash between delivery and this
+// call resends those items in the next digest.
+func ClearPendingDigestItems(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	stmt, err := tx.Prepare("DELETE FROM pending_digest WHERE id = ?")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare delete: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if _, err := stmt.Exec(id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to clear pending digest item %d: %w", id, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCachedArticle returns a previously extracted full-article body for an
+// item, if one was cached by a prior fetch_full_content run.
+func GetCachedArticle(itemGUID string) (content string, found bool, err error) {
+	query := "SELECT content FROM article_cache WHERE item_guid = ?"
+	err = db.QueryRow(query, itemGUID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get cached article: %w", err)
+	}
+	return content, true, nil
+}
+
+func CacheArticle(itemGUID, content string) error {
+	query := `INSERT INTO article_cache (item_guid, content, fetched_at) VALUES (?, ?, ?)
+	          ON CONFLICT(item_guid) DO UPDATE SET
+	            content = excluded.content,
+	            fetched_at = excluded.fetched_at`
+	_, err := db.Exec(query, itemGUID, content, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to cache article: %w", err)
+	}
+	return nil
+}
+
 func HasFeedItems(feedURL string) (bool, error) {
 	var count int
 	query := "SELECT COUNT(*) FROM sent_items WHERE feed_url = ?"
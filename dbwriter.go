@@ -0,0 +1,25 @@
+package main
+
+// dbWriteOp is a single DB mutation submitted to the writer goroutine, with
+// a buffered done channel so the submitter can still wait for its result.
+type dbWriteOp struct {
+	run  func() error
+	done chan error
+}
+
+// runDBWriter serializes all DB writes from a poll tick's worker pool
+// through a single goroutine, since concurrent writers otherwise contend
+// for SQLite's single writer lock.
+func runDBWriter(writes <-chan dbWriteOp) {
+	for op := range writes {
+		op.done <- op.run()
+	}
+}
+
+// submitWrite hands run to the writer goroutine and blocks for its result,
+// so callers keep the same error-handling shape as a direct DB call.
+func submitWrite(writes chan<- dbWriteOp, run func() error) error {
+	op := dbWriteOp{run: run, done: make(chan error, 1)}
+	writes <- op
+	return <-op.done
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// runDigestScheduler wakes up on scheduleExpr (a standard 5-field cron
+// expression) and sends whatever is queued in pending_digest through sender.
+// It runs until stop is closed.
+func runDigestScheduler(scheduleExpr string, sender Delivery, stop <-chan struct{}) {
+	schedule, err := cron.ParseStandard(scheduleExpr)
+	if err != nil {
+		log.Printf("Invalid digest_schedule %q, digest mode disabled: %v", scheduleExpr, err)
+		return
+	}
+
+	for {
+		next := schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-timer.C:
+			if err := SendPendingDigest(sender); err != nil {
+				log.Printf("Error sending digest: %v", err)
+			}
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// feedDigestGroup is one feed's items within a digest email, sorted by
+// published date.
+type feedDigestGroup struct {
+	FeedName string
+	Items    []DigestItem
+}
+
+var digestHTMLTemplate = template.Must(template.New("digest").Parse(`
+<html>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <h2 style="color: #2c3e50;">RSS digest: {{len .Items}} new item(s)</h2>
+    {{range .Groups}}
+    <h3 style="color: #2c3e50; border-bottom: 1px solid #ecf0f1;">{{.FeedName}}</h3>
+    {{range .Items}}
+    <div style="background-color: #f8f9fa; padding: 15px; border-left: 4px solid #3498db; margin: 20px 0;">
+        <h4 style="margin-top: 0;">
+            <a href="{{.Link}}" style="color: #2980b9; text-decoration: none;">{{.Title}}</a>
+        </h4>
+        <p style="color: #7f8c8d; font-size: 0.9em;"><strong>Published:</strong> {{.Published}}</p>
+        <div>{{.Summary}}</div>
+    </div>
+    {{end}}
+    {{end}}
+    <div style="margin-top: 30px; padding-top: 20px; border-top: 1px solid #ecf0f1;">
+        <p style="color: #95a5a6; font-size: 0.85em;">This email was sent by RSS to Email service.</p>
+    </div>
+</body>
+</html>
+`))
+
+// SendPendingDigest assembles every item queued in pending_digest into a
+// single grouped email (grouped by feed, sorted by published date), hands it
+// to sender, and only then clears the queued rows: a crash before delivery
+// loses nothing (the rows are still pending), but a crash after delivery
+// and before the clear resends those items in the next digest.
+func SendPendingDigest(sender Delivery) error {
+	items, err := GetPendingDigestItems()
+	if err != nil {
+		return fmt.Errorf("failed to get pending digest items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	groups := groupDigestItems(items)
+	subject, textBody, htmlBody, err := formatDigestEmail(items, groups)
+	if err != nil {
+		return fmt.Errorf("failed to format digest email: %w", err)
+	}
+
+	if err := sender.DeliverDigest(subject, textBody, htmlBody); err != nil {
+		return fmt.Errorf("failed to deliver digest: %w", err)
+	}
+
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	if err := ClearPendingDigestItems(ids); err != nil {
+		return fmt.Errorf("failed to clear pending digest items: %w", err)
+	}
+
+	return nil
+}
+
+// templateDigestItem mirrors DigestItem for rendering, marking Summary as
+// trusted HTML since feeds are expected to publish HTML-formatted content
+// (matching FormatRSSEmail's non-escaping treatment of item.Summary).
+type templateDigestItem struct {
+	Title     string
+	Link      string
+	Published string
+	Summary   template.HTML
+}
+
+type templateDigestGroup struct {
+	FeedName string
+	Items    []templateDigestItem
+}
+
+func toTemplateGroups(groups []feedDigestGroup) []templateDigestGroup {
+	out := make([]templateDigestGroup, len(groups))
+	for i, group := range groups {
+		tItems := make([]templateDigestItem, len(group.Items))
+		for j, item := range group.Items {
+			tItems[j] = templateDigestItem{
+				Title:     item.Title,
+				Link:      item.Link,
+				Published: item.Published,
+				Summary:   template.HTML(item.Summary),
+			}
+		}
+		out[i] = templateDigestGroup{FeedName: group.FeedName, Items: tItems}
+	}
+	return out
+}
+
+func groupDigestItems(items []DigestItem) []feedDigestGroup {
+	var groups []feedDigestGroup
+	byFeed := make(map[string]int)
+
+	for _, item := range items {
+		idx, ok := byFeed[item.FeedName]
+		if !ok {
+			idx = len(groups)
+			byFeed[item.FeedName] = idx
+			groups = append(groups, feedDigestGroup{FeedName: item.FeedName})
+		}
+		groups[idx].Items = append(groups[idx].Items, item)
+	}
+
+	return groups
+}
+
+func formatDigestEmail(items []DigestItem, groups []feedDigestGroup) (subject, textBody, htmlBody string, err error) {
+	subject = fmt.Sprintf("[RSS] Digest: %d new item(s)", len(items))
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "RSS digest: %d new item(s)\n\n", len(items))
+	for _, group := range groups {
+		fmt.Fprintf(&text, "== %s ==\n\n", group.FeedName)
+		for _, item := range group.Items {
+			fmt.Fprintf(&text, "Title: %s\nLink: %s\nPublished: %s\n\n%s\n\n", item.Title, item.Link, item.Published, item.Summary)
+		}
+	}
+	text.WriteString("---\nThis email was sent by RSS to Email service.\n")
+	textBody = text.String()
+
+	var html strings.Builder
+	if err := digestHTMLTemplate.Execute(&html, struct {
+		Items  []DigestItem
+		Groups []templateDigestGroup
+	}{Items: items, Groups: toTemplateGroups(groups)}); err != nil {
+		return "", "", "", err
+	}
+	htmlBody = html.String()
+
+	return subject, textBody, htmlBody, nil
+}
@@ -11,6 +11,15 @@ const (
 	smtpPort   = "587"
 )
 
+// Delivery hands a single feed item, or an already-rendered digest, off to
+// whatever backend the user configured (SMTP, IMAP, ...). For a single item,
+// implementations are responsible for composing and storing/sending the
+// message.
+type Delivery interface {
+	Deliver(feedName string, item FeedItem) error
+	DeliverDigest(subject, textBody, htmlBody string) error
+}
+
 type Sender struct {
 	gmailAddress     string
 	gmailAppPassword string
@@ -25,9 +34,18 @@ func NewSender(gmailAddress, gmailAppPassword, recipientEmail string) *Sender {
 	}
 }
 
+func (s *Sender) Deliver(feedName string, item FeedItem) error {
+	subject, textBody, htmlBody := FormatRSSEmail(feedName, item)
+	return s.SendEmail(subject, textBody, htmlBody)
+}
+
+func (s *Sender) DeliverDigest(subject, textBody, htmlBody string) error {
+	return s.SendEmail(subject, textBody, htmlBody)
+}
+
 func (s *Sender) SendEmail(subject, textBody, htmlBody string) error {
 	auth := smtp.PlainAuth("", s.gmailAddress, s.gmailAppPassword, smtpServer)
-	msg := s.composeMIMEMessage(subject, textBody, htmlBody)
+	msg := composeMIMEMessage(s.gmailAddress, s.recipientEmail, subject, textBody, htmlBody)
 	addr := smtpServer + ":" + smtpPort
 	err := smtp.SendMail(addr, auth, s.gmailAddress, []string{s.recipientEmail}, []byte(msg))
 	if err != nil {
@@ -36,12 +54,12 @@ func (s *Sender) SendEmail(subject, textBody, htmlBody string) error {
 	return nil
 }
 
-func (s *Sender) composeMIMEMessage(subject, textBody, htmlBody string) string {
+func composeMIMEMessage(from, to, subject, textBody, htmlBody string) string {
 	boundary := "----=_Part_0_1234567890.1234567890"
 
 	headers := []string{
-		fmt.Sprintf("From: %s", s.gmailAddress),
-		fmt.Sprintf("To: %s", s.recipientEmail),
+		fmt.Sprintf("From: %s", from),
+		fmt.Sprintf("To: %s", to),
 		fmt.Sprintf("Subject: %s", subject),
 		"MIME-Version: 1.0",
 		fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"", boundary),
@@ -69,7 +87,11 @@ func (s *Sender) composeMIMEMessage(subject, textBody, htmlBody string) string {
 }
 
 func FormatRSSEmail(feedName string, item FeedItem) (subject, textBody, htmlBody string) {
-	subject = fmt.Sprintf("[RSS] %s: %s", feedName, item.Title)
+	tag := "[RSS]"
+	if item.Updated {
+		tag = "[RSS][Updated]"
+	}
+	subject = fmt.Sprintf("%s %s: %s", tag, feedName, item.Title)
 
 	textBody = fmt.Sprintf(`
 New post from %s
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// SeedFeeds adds each of the given feeds to the feeds table, but only while
+// that table is still empty (first run). It's called at startup with
+// config.yaml's feeds so existing deployments keep working after the feed
+// list moved into SQLite; later changes go through the admin API or OPML.
+func SeedFeeds(feeds []FeedConfig) error {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM feeds").Scan(&count); err != nil {
+		return fmt.Errorf("failed to count feeds: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, feed := range feeds {
+		if err := AddFeedIfMissing(feed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func AddFeedIfMissing(feed FeedConfig) error {
+	filtersJSON, err := json.Marshal(feed.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode filters for %s: %w", feed.URL, err)
+	}
+
+	query := `INSERT INTO feeds (url, mode, fetch_full_content, user_agent, filters_json) VALUES (?, ?, ?, ?, ?) ON CONFLICT(url) DO NOTHING`
+	_, err = db.Exec(query, feed.URL, feed.Mode, feed.FetchFullContent, feed.UserAgent, string(filtersJSON))
+	if err != nil {
+		return fmt.Errorf("failed to seed feed %s: %w", feed.URL, err)
+	}
+	return nil
+}
+
+func ListFeeds() ([]FeedConfig, error) {
+	rows, err := db.Query("SELECT url, mode, fetch_full_content, user_agent, filters_json FROM feeds ORDER BY url")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []FeedConfig
+	for rows.Next() {
+		var feed FeedConfig
+		var filtersJSON string
+		if err := rows.Scan(&feed.URL, &feed.Mode, &feed.FetchFullContent, &feed.UserAgent, &filtersJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan feed: %w", err)
+		}
+		if filtersJSON != "" {
+			if err := json.Unmarshal([]byte(filtersJSON), &feed.Filters); err != nil {
+				return nil, fmt.Errorf("failed to decode filters for %s: %w", feed.URL, err)
+			}
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, rows.Err()
+}
+
+// AddFeed adds a feed, or updates its settings if it's already subscribed.
+func AddFeed(feed FeedConfig) error {
+	filtersJSON, err := json.Marshal(feed.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode filters for %s: %w", feed.URL, err)
+	}
+
+	query := `INSERT INTO feeds (url, mode, fetch_full_content, user_agent, filters_json) VALUES (?, ?, ?, ?, ?)
+	          ON CONFLICT(url) DO UPDATE SET
+	            mode = excluded.mode,
+	            fetch_full_content = excluded.fetch_full_content,
+	            user_agent = excluded.user_agent,
+	            filters_json = excluded.filters_json`
+	_, err = db.Exec(query, feed.URL, feed.Mode, feed.FetchFullContent, feed.UserAgent, string(filtersJSON))
+	if err != nil {
+		return fmt.Errorf("failed to add feed %s: %w", feed.URL, err)
+	}
+	return nil
+}
+
+// RemoveFeed unsubscribes a feed. It returns sql.ErrNoRows if the feed
+// wasn't subscribed.
+func RemoveFeed(url string) error {
+	result, err := db.Exec("DELETE FROM feeds WHERE url = ?", url)
+	if err != nil {
+		return fmt.Errorf("failed to remove feed %s: %w", url, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"time"
+)
+
+// FilterConfig declares per-feed include/exclude rules checked against each
+// item before it's sent. Every regex field is optional; an empty list means
+// "no restriction" for that rule. MinAge/MaxAge are Go duration strings
+// (e.g. "1h", "24h") compared against the item's published time.
+type FilterConfig struct {
+	IncludeTitle      []string `yaml:"include_title" json:"include_title,omitempty"`
+	ExcludeTitle      []string `yaml:"exclude_title" json:"exclude_title,omitempty"`
+	IncludeCategories []string `yaml:"include_categories" json:"include_categories,omitempty"`
+	ExcludeAuthors    []string `yaml:"exclude_authors" json:"exclude_authors,omitempty"`
+	MinAge            string   `yaml:"min_age" json:"min_age,omitempty"`
+	MaxAge            string   `yaml:"max_age" json:"max_age,omitempty"`
+}
+
+// matchesAny reports whether value matches at least one of patterns. An
+// invalid pattern is logged and skipped rather than treated as a match.
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Printf("Warning: invalid filter pattern %q: %v", pattern, err)
+			continue
+		}
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldFilterItem reports whether item should be withheld from delivery
+// under feed's filter rules.
+func shouldFilterItem(feed FeedConfig, item FeedItem) bool {
+	f := feed.Filters
+
+	if len(f.IncludeTitle) > 0 && !matchesAny(f.IncludeTitle, item.Title) {
+		return true
+	}
+	if matchesAny(f.ExcludeTitle, item.Title) {
+		return true
+	}
+
+	if len(f.IncludeCategories) > 0 {
+		matched := false
+		for _, category := range item.Categories {
+			if matchesAny(f.IncludeCategories, category) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return true
+		}
+	}
+
+	if matchesAny(f.ExcludeAuthors, item.Author) {
+		return true
+	}
+
+	if item.PublishedDT != nil {
+		age := time.Since(*item.PublishedDT)
+		if f.MinAge != "" {
+			if minAge, err := time.ParseDuration(f.MinAge); err == nil && age < minAge {
+				return true
+			}
+		}
+		if f.MaxAge != "" {
+			if maxAge, err := time.ParseDuration(f.MaxAge); err == nil && age > maxAge {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// filterItems splits items by feed's filter rules. Items that pass are
+// returned for delivery; filtered-out items are marked as sent (so they
+// don't accumulate and get re-evaluated on every poll) without being
+// emailed, unless dryRun is set, in which case nothing is marked and the
+// decision is only logged.
+func filterItems(feedURL string, feed FeedConfig, items []FeedItem, writes chan dbWriteOp) []FeedItem {
+	kept := make([]FeedItem, 0, len(items))
+	for _, item := range items {
+		if !shouldFilterItem(feed, item) {
+			kept = append(kept, item)
+			continue
+		}
+
+		if dryRun {
+			log.Printf("[dry-run] Would filter: %s for %s", item.Title, feedURL)
+			continue
+		}
+
+		log.Printf("Filtered: %s for %s", item.Title, feedURL)
+		item := item
+		if err := submitWrite(writes, func() error {
+			return MarkItemSent(feedURL, item.GUID, ContentHash(item))
+		}); err != nil {
+			log.Printf("Error marking filtered item as sent: %v", err)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap/client"
+)
+
+// IMAPDelivery appends RSS items as messages to an IMAP folder instead of
+// mailing them out, one folder per feed (see folderTemplate). This lets
+// users read their feeds from any IMAP client without polluting their inbox.
+type IMAPDelivery struct {
+	server         string
+	port           string
+	username       string
+	password       string
+	useTLS         bool
+	folderTemplate string
+}
+
+func NewIMAPDelivery(server, port, username, password string, useTLS bool, folderTemplate string) *IMAPDelivery {
+	return &IMAPDelivery{
+		server:         server,
+		port:           port,
+		username:       username,
+		password:       password,
+		useTLS:         useTLS,
+		folderTemplate: folderTemplate,
+	}
+}
+
+func (d *IMAPDelivery) Deliver(feedName string, item FeedItem) error {
+	subject, textBody, htmlBody := FormatRSSEmail(feedName, item)
+	msg := composeMIMEMessage(d.username, d.username, subject, textBody, htmlBody)
+
+	c, err := d.dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect to IMAP server: %w", err)
+	}
+	defer c.Logout()
+
+	folder := d.folderName(feedName)
+	if err := d.ensureFolder(c, folder); err != nil {
+		return fmt.Errorf("failed to ensure IMAP folder %q: %w", folder, err)
+	}
+
+	internalDate := time.Now()
+	if item.PublishedDT != nil {
+		internalDate = *item.PublishedDT
+	}
+
+	// Leave \Seen and \Draft unset so the item shows up as a normal unread message.
+	var flags []string
+	if err := c.Append(folder, flags, internalDate, strings.NewReader(msg)); err != nil {
+		return fmt.Errorf("failed to append message to %q: %w", folder, err)
+	}
+
+	return nil
+}
+
+// DeliverDigest appends a single digest message, grouping multiple feeds'
+// worth of items, to a dedicated "Digest" folder rather than a per-feed one.
+func (d *IMAPDelivery) DeliverDigest(subject, textBody, htmlBody string) error {
+	msg := composeMIMEMessage(d.username, d.username, subject, textBody, htmlBody)
+
+	c, err := d.dial()
+	if err != nil {
+		return fmt.Errorf("failed to connect to IMAP server: %w", err)
+	}
+	defer c.Logout()
+
+	folder := d.folderName("Digest")
+	if err := d.ensureFolder(c, folder); err != nil {
+		return fmt.Errorf("failed to ensure IMAP folder %q: %w", folder, err)
+	}
+
+	var flags []string
+	if err := c.Append(folder, flags, time.Now(), strings.NewReader(msg)); err != nil {
+		return fmt.Errorf("failed to append digest message to %q: %w", folder, err)
+	}
+
+	return nil
+}
+
+func (d *IMAPDelivery) dial() (*client.Client, error) {
+	addr := d.server + ":" + d.port
+
+	var c *client.Client
+	var err error
+	if d.useTLS {
+		c, err = client.DialTLS(addr, nil)
+	} else {
+		c, err = client.Dial(addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+
+	if err := c.Login(d.username, d.password); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	return c, nil
+}
+
+func (d *IMAPDelivery) ensureFolder(c *client.Client, folder string) error {
+	if err := c.Create(folder); err != nil && !strings.Contains(strings.ToLower(err.Error()), "already exists") {
+		return err
+	}
+	return nil
+}
+
+func (d *IMAPDelivery) folderName(feedName string) string {
+	return strings.ReplaceAll(d.folderTemplate, "{feedName}", feedName)
+}
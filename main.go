@@ -1,24 +1,41 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
 	"time"
+
+	"golang.org/x/sync/errgroup"
 )
 
 var (
 	cfg         *Config
-	emailSender *Sender
+	emailSender Delivery
+	dryRun      bool
 )
 
 const StandardInterval = 60 * time.Minute
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "opml" {
+		if err := runOPMLCommand(os.Args[2:]); err != nil {
+			log.Fatalf("opml command failed: %v", err)
+		}
+		return
+	}
+
+	flag.BoolVar(&dryRun, "dry-run", false, "log what would be sent or filtered without delivering mail or touching the sent_items table")
+	flag.Parse()
+
 	var err error
 	cfg, err = Load("config.yaml")
 	if err != nil {
@@ -30,7 +47,16 @@ func main() {
 	}
 	defer Close()
 
-	emailSender = NewSender(cfg.GmailAppPassword)
+	if err := SeedFeeds(cfg.Feeds); err != nil {
+		log.Fatalf("Failed to seed feeds: %v", err)
+	}
+
+	switch cfg.Delivery {
+	case "imap":
+		emailSender = NewIMAPDelivery(cfg.IMAP.Server, cfg.IMAP.Port, cfg.IMAP.Username, cfg.IMAP.Password, cfg.IMAP.TLS, cfg.IMAP.FolderTemplate)
+	default:
+		emailSender = NewSender(cfg.GmailAddress, cfg.GmailAppPassword, cfg.RecipientEmail)
+	}
 
 	checkFeeds()
 
@@ -49,6 +75,22 @@ func main() {
 		}
 	}()
 
+	// Started whenever not in dry-run mode, rather than gated on a digest-mode
+	// feed existing at boot: the admin API lets an operator add or switch a
+	// feed to digest mode later, and SendPendingDigest no-ops cheaply while
+	// pending_digest is empty.
+	if !dryRun {
+		go runDigestScheduler(cfg.DigestSchedule, emailSender, stopChan)
+	}
+
+	if cfg.Admin.Enabled {
+		go runAdminServer(cfg.Admin)
+	}
+
+	if cfg.Metrics.Enabled {
+		go runMetricsServer(cfg.Metrics.Addr)
+	}
+
 	log.Printf("Scheduler started - checking feeds every %v", StandardInterval)
 
 	sigChan := make(chan os.Signal, 1)
@@ -60,83 +102,148 @@ func main() {
 	time.Sleep(100 * time.Millisecond)
 }
 
+// checkFeeds polls every subscribed feed, fanning the work out across a
+// bounded worker pool (cfg.PollConcurrency workers) so one slow server
+// doesn't hold up the rest. Feeds are shuffled first to spread load across
+// hosts evenly tick over tick. Per-feed DB writes are funneled through a
+// single writer goroutine since concurrent writers contend for SQLite's
+// single writer lock.
 func checkFeeds() {
 	log.Println("Checking feeds...")
 
-	for _, feedURL := range cfg.Feeds {
-		metadata, err := GetFeedMetadata(feedURL)
-		if err != nil {
-			log.Printf("Error getting metadata for %s: %v", feedURL, err)
-			continue
-		}
+	feeds, err := ListFeeds()
+	if err != nil {
+		log.Printf("Error listing feeds: %v", err)
+		return
+	}
 
-		// FRB037 & Backoff: Check if it's time to poll
-		if metadata != nil {
-			if metadata.NextCheckAfter != nil && time.Now().Before(*metadata.NextCheckAfter) ||
-				time.Since(metadata.LastChecked) < StandardInterval {
-				log.Printf("Skipping %s, next check after %v", feedURL, metadata.NextCheckAfter)
-				continue
-			}
-		}
+	rand.Shuffle(len(feeds), func(i, j int) { feeds[i], feeds[j] = feeds[j], feeds[i] })
 
-		lastModified := ""
-		etag := ""
-		if metadata != nil {
-			lastModified = metadata.LastModified
-			etag = metadata.ETag
-		}
+	writes := make(chan dbWriteOp)
+	writerStopped := make(chan struct{})
+	go func() {
+		defer close(writerStopped)
+		runDBWriter(writes)
+	}()
 
-		result, err := FetchFeed(feedURL, lastModified, etag)
-		if err != nil {
-			log.Printf("Error fetching feed %s: %v", feedURL, err)
+	limiter := newHostLimiter()
 
-			status := 0
-			retryAfter := ""
-			if result != nil {
-				status = result.StatusCode
-				retryAfter = result.RetryAfter
-			}
+	g := new(errgroup.Group)
+	g.SetLimit(cfg.PollConcurrency)
 
-			currentErrorCount := 0
-			if metadata != nil {
-				currentErrorCount = metadata.ErrorCount
-			}
-			newErrorCount := currentErrorCount + 1
+	for _, feed := range feeds {
+		feed := feed
+		g.Go(func() error {
+			pollFeed(feed, limiter, writes)
+			return nil
+		})
+	}
 
-			nextCheck := calculateBackoff(status, retryAfter, newErrorCount)
+	g.Wait()
+	close(writes)
+	<-writerStopped
 
-			// FRB016: Only update status/error/schedule, keep old cache headers
-			if err := UpdateFeedError(feedURL, status, newErrorCount, nextCheck); err != nil {
-				log.Printf("Error updating status for %s: %v", feedURL, err)
-			}
-			continue
+	log.Println("Done checking feeds.")
+}
+
+// pollFeed fetches and processes a single feed. It's safe to call
+// concurrently from multiple workers; all of its DB writes go through
+// writes rather than hitting the database directly.
+func pollFeed(feed FeedConfig, limiter *hostLimiter, writes chan dbWriteOp) {
+	feedURL := feed.URL
+	mode := cfg.ResolveMode(feed)
+
+	metadata, err := GetFeedMetadata(feedURL)
+	if err != nil {
+		log.Printf("Error getting metadata for %s: %v", feedURL, err)
+		return
+	}
+
+	// FRB037 & Backoff: Check if it's time to poll
+	if metadata != nil {
+		if metadata.NextCheckAfter != nil && time.Now().Before(*metadata.NextCheckAfter) ||
+			time.Since(metadata.LastChecked) < StandardInterval {
+			log.Printf("Skipping %s, next check after %v", feedURL, metadata.NextCheckAfter)
+			return
 		}
+	}
+
+	lastModified := ""
+	etag := ""
+	if metadata != nil {
+		lastModified = metadata.LastModified
+		etag = metadata.ETag
+	}
 
-		// Success or 304
-		// Standard interval
-		nextCheck := time.Now().Add(StandardInterval)
-		if err := UpdateFeedSuccess(feedURL, result.LastModified, result.ETag, result.StatusCode, nextCheck); err != nil {
-			log.Printf("Error updating metadata for %s: %v", feedURL, err)
+	if parsedURL, err := url.Parse(feedURL); err == nil {
+		if err := limiter.wait(context.Background(), parsedURL.Host); err != nil {
+			log.Printf("Error rate-limiting %s: %v", feedURL, err)
+			return
 		}
+	}
 
-		if result.NotModified || len(result.Items) == 0 {
-			continue
+	start := time.Now()
+	result, err := FetchFeed(feed, lastModified, etag)
+	observeFetch(start)
+	if err != nil {
+		log.Printf("Error fetching feed %s: %v", feedURL, err)
+
+		status := 0
+		retryAfter := ""
+		if result != nil {
+			status = result.StatusCode
+			retryAfter = result.RetryAfter
 		}
 
-		hasFeedItems, err := HasFeedItems(feedURL)
-		if err != nil {
-			log.Printf("Error checking feed items for %s: %v", feedURL, err)
-			continue
+		currentErrorCount := 0
+		if metadata != nil {
+			currentErrorCount = metadata.ErrorCount
 		}
+		newErrorCount := currentErrorCount + 1
 
-		if hasFeedItems {
-			processExistingFeed(feedURL, result.FeedTitle, result.Items)
-		} else {
-			processNewFeed(feedURL, result.FeedTitle, result.Items)
+		nextCheck := calculateBackoff(status, retryAfter, newErrorCount)
+
+		// FRB016: Only update status/error/schedule, keep old cache headers
+		if err := submitWrite(writes, func() error {
+			return UpdateFeedError(feedURL, status, newErrorCount, nextCheck)
+		}); err != nil {
+			log.Printf("Error updating status for %s: %v", feedURL, err)
 		}
+		return
 	}
 
-	log.Println("Done checking feeds.")
+	// Success or 304
+	// Standard interval
+	nextCheck := time.Now().Add(StandardInterval)
+	if err := submitWrite(writes, func() error {
+		return UpdateFeedSuccess(feedURL, result.LastModified, result.ETag, result.StatusCode, nextCheck)
+	}); err != nil {
+		log.Printf("Error updating metadata for %s: %v", feedURL, err)
+	}
+
+	if result.NotModified || len(result.Items) == 0 {
+		return
+	}
+
+	// Decide new-vs-existing before filtering: filterItems marks filtered
+	// items as sent, and doing that first would flip HasFeedItems to true
+	// mid-tick and rob a brand-new feed of its backlog-suppressing first poll.
+	hasFeedItems, err := HasFeedItems(feedURL)
+	if err != nil {
+		log.Printf("Error checking feed items for %s: %v", feedURL, err)
+		return
+	}
+
+	items := filterItems(feedURL, feed, result.Items, writes)
+	if len(items) == 0 {
+		return
+	}
+
+	if hasFeedItems {
+		processExistingFeed(feedURL, feed, result.FeedTitle, mode, items, writes)
+	} else {
+		processNewFeed(feedURL, feed, result.FeedTitle, mode, items, writes)
+	}
 }
 
 func calculateBackoff(status int, retryAfter string, errorCount int) time.Time {
@@ -187,50 +294,87 @@ func parseRetryAfter(header string) time.Duration {
 	return 0
 }
 
-func processNewFeed(feedURL, feedName string, items []FeedItem) {
+func processNewFeed(feedURL string, feed FeedConfig, feedName, mode string, items []FeedItem, writes chan dbWriteOp) {
 	mostRecent := GetMostRecentItem(items)
 	if mostRecent == nil {
 		return
 	}
 
-	sendItem(feedURL, feedName, *mostRecent)
+	sendItem(feedURL, feed, feedName, mode, *mostRecent, writes)
+
+	if dryRun {
+		return
+	}
 
 	for _, item := range items {
 		if item.GUID != mostRecent.GUID {
-			if err := MarkItemSent(feedURL, item.GUID); err != nil {
+			item := item
+			if err := submitWrite(writes, func() error {
+				return MarkItemSent(feedURL, item.GUID, ContentHash(item))
+			}); err != nil {
 				log.Printf("Error marking item as sent: %v", err)
 			}
 		}
 	}
 }
 
-func processExistingFeed(feedURL, feedName string, items []FeedItem) {
+func processExistingFeed(feedURL string, feed FeedConfig, feedName, mode string, items []FeedItem, writes chan dbWriteOp) {
 	for _, item := range items {
-		isSent, err := IsItemSent(feedURL, item.GUID)
+		sent, changed, err := IsItemSent(feedURL, item.GUID, ContentHash(item))
 		if err != nil {
 			log.Printf("Error checking if item is sent: %v", err)
 			continue
 		}
 
-		if !isSent {
-			sendItem(feedURL, feedName, item)
+		if !sent {
+			sendItem(feedURL, feed, feedName, mode, item, writes)
+		} else if changed {
+			item.Updated = true
+			sendItem(feedURL, feed, feedName, mode, item, writes)
 		}
 	}
 }
 
-func sendItem(feedURL, feedName string, item FeedItem) {
-	subject, textBody, htmlBody := FormatRSSEmail(feedName, item)
-
-	if err := emailSender.SendEmail(subject, textBody, htmlBody); err != nil {
-		log.Printf("Error sending email for %s: %v", item.Title, err)
+func sendItem(feedURL string, feed FeedConfig, feedName, mode string, item FeedItem, writes chan dbWriteOp) {
+	if dryRun {
+		log.Printf("[dry-run] Would send: %s for %s (mode=%s)", item.Title, feedURL, mode)
 		return
 	}
 
-	if err := MarkItemSent(feedURL, item.GUID); err != nil {
+	// Hash the feed-provided content, so the stored hash matches what
+	// processExistingFeed computes on the next poll.
+	contentHash := ContentHash(item)
+
+	if feed.FetchFullContent {
+		item.Summary = resolveFullContent(item, feed.UserAgent, writes)
+	}
+
+	if mode == digestMode {
+		if err := submitWrite(writes, func() error {
+			return QueueDigestItem(feedURL, feedName, item)
+		}); err != nil {
+			log.Printf("Error queuing digest item: %v", err)
+			return
+		}
+	} else {
+		if err := emailSender.Deliver(feedName, item); err != nil {
+			log.Printf("Error delivering %s: %v", item.Title, err)
+			return
+		}
+	}
+
+	if err := submitWrite(writes, func() error {
+		return MarkItemSent(feedURL, item.GUID, contentHash)
+	}); err != nil {
 		log.Printf("Error marking item as sent: %v", err)
 		return
 	}
 
+	if mode == digestMode {
+		log.Printf("Queued for digest: %s for %s", item.Title, feedURL)
+		return
+	}
+
 	log.Printf("Sent: %s for %s", item.Title, feedURL)
 
 	time.Sleep(1 * time.Second) // Rate limiting
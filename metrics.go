@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	feedsPolledTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rss_email_feeds_polled_total",
+		Help: "Total number of feed poll attempts.",
+	})
+
+	fetchLatencySeconds = prometheus.NewSummary(prometheus.SummaryOpts{
+		Name:       "rss_email_fetch_latency_seconds",
+		Help:       "Feed fetch latency in seconds.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+	})
+)
+
+func init() {
+	prometheus.MustRegister(feedsPolledTotal, fetchLatencySeconds)
+}
+
+// observeFetch records a completed feed fetch that started at start.
+func observeFetch(start time.Time) {
+	feedsPolledTotal.Inc()
+	fetchLatencySeconds.Observe(time.Since(start).Seconds())
+}
+
+// runMetricsServer serves Prometheus metrics at /metrics. It's bound to
+// metrics.Addr, localhost by default, same as the admin API.
+func runMetricsServer(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	log.Printf("Metrics endpoint listening on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Printf("Metrics server stopped: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// ParseOPML extracts the feed subscriptions from an OPML document, e.g. one
+// exported from Feedly or Inoreader. Feeds nested under category/folder
+// outlines (as both services export them) are walked recursively.
+func ParseOPML(data []byte) ([]FeedConfig, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OPML: %w", err)
+	}
+
+	var feeds []FeedConfig
+	collectOPMLFeeds(doc.Body.Outlines, &feeds)
+	return feeds, nil
+}
+
+// collectOPMLFeeds appends every feed found in outlines to feeds, recursing
+// into folder outlines that have no xmlUrl of their own.
+func collectOPMLFeeds(outlines []opmlOutline, feeds *[]FeedConfig) {
+	for _, outline := range outlines {
+		if outline.XMLURL != "" {
+			*feeds = append(*feeds, FeedConfig{URL: outline.XMLURL})
+		}
+		collectOPMLFeeds(outline.Outlines, feeds)
+	}
+}
+
+// ExportOPML renders the given feeds as an OPML document.
+func ExportOPML(feeds []FeedConfig) ([]byte, error) {
+	doc := opmlDocument{
+		Version: "2.0",
+		Body:    opmlBody{Outlines: make([]opmlOutline, len(feeds))},
+	}
+	for i, feed := range feeds {
+		doc.Body.Outlines[i] = opmlOutline{
+			Text:   feed.URL,
+			Type:   "rss",
+			XMLURL: feed.URL,
+		}
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OPML: %w", err)
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// runOPMLCommand implements the "opml" CLI subcommand: `rss_email opml
+// import <file>` and `rss_email opml export [file]`.
+func runOPMLCommand(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: rss_email opml <import|export> [file]")
+	}
+
+	var err error
+	cfg, err = Load("config.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if err := Initialize("data/rss_email.db"); err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer Close()
+
+	switch args[0] {
+	case "import":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: rss_email opml import <file>")
+		}
+		return importOPMLFile(args[1])
+	case "export":
+		path := "feeds.opml"
+		if len(args) >= 2 {
+			path = args[1]
+		}
+		return exportOPMLFile(path)
+	default:
+		return fmt.Errorf("unknown opml subcommand %q", args[0])
+	}
+}
+
+func importOPMLFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read OPML file: %w", err)
+	}
+
+	feeds, err := ParseOPML(data)
+	if err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		if err := AddFeed(feed); err != nil {
+			return fmt.Errorf("failed to add feed %s: %w", feed.URL, err)
+		}
+	}
+
+	log.Printf("Imported %d feed(s) from %s", len(feeds), path)
+	return nil
+}
+
+func exportOPMLFile(path string) error {
+	feeds, err := ListFeeds()
+	if err != nil {
+		return err
+	}
+
+	data, err := ExportOPML(feeds)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write OPML file: %w", err)
+	}
+
+	log.Printf("Exported %d feed(s) to %s", len(feeds), path)
+	return nil
+}
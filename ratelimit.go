@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// perHostInterval bounds how often we'll hit any single origin, regardless
+// of how many feeds on that host are due for a poll in the same tick.
+const perHostInterval = 2 * time.Second
+
+// hostLimiter hands out a per-host token bucket so concurrent workers never
+// hammer one origin even when they're polling many feeds from it at once.
+type hostLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newHostLimiter() *hostLimiter {
+	return &hostLimiter{limiters: make(map[string]*rate.Limiter)}
+}
+
+// wait blocks until host's bucket allows another request.
+func (h *hostLimiter) wait(ctx context.Context, host string) error {
+	h.mu.Lock()
+	limiter, ok := h.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(perHostInterval), 1)
+		h.limiters[host] = limiter
+	}
+	h.mu.Unlock()
+
+	return limiter.Wait(ctx)
+}
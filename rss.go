@@ -17,6 +17,9 @@ type FeedItem struct {
 	Published   string
 	PublishedDT *time.Time
 	Summary     string
+	Updated     bool
+	Author      string
+	Categories  []string
 }
 
 type FeedResult struct {
@@ -24,12 +27,15 @@ type FeedResult struct {
 	Items        []FeedItem
 	LastModified string
 	ETag         string
+	RetryAfter   string
 	StatusCode   int
 	NotModified  bool
 	RateLimited  bool
 }
 
-func FetchFeed(url string, lastModified, etag string) (*FeedResult, error) {
+func FetchFeed(feed FeedConfig, lastModified, etag string) (*FeedResult, error) {
+	url := feed.URL
+
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -62,6 +68,7 @@ func FetchFeed(url string, lastModified, etag string) (*FeedResult, error) {
 		StatusCode:   resp.StatusCode,
 		LastModified: resp.Header.Get("Last-Modified"),
 		ETag:         resp.Header.Get("ETag"),
+		RetryAfter:   resp.Header.Get("Retry-After"),
 	}
 
 	if resp.StatusCode == http.StatusNotModified {
@@ -80,26 +87,27 @@ func FetchFeed(url string, lastModified, etag string) (*FeedResult, error) {
 	}
 
 	fp := gofeed.NewParser()
-	feed, err := fp.Parse(resp.Body)
+	parsedFeed, err := fp.Parse(resp.Body)
 	if err != nil {
 		return result, fmt.Errorf("failed to parse feed: %w", err)
 	}
 
 	feedTitle := url
-	if feed.Title != "" {
-		feedTitle = feed.Title
+	if parsedFeed.Title != "" {
+		feedTitle = parsedFeed.Title
 	}
 	result.FeedTitle = feedTitle
 
-	items := make([]FeedItem, 0, len(feed.Items))
-	for _, entry := range feed.Items {
+	items := make([]FeedItem, 0, len(parsedFeed.Items))
+	for _, entry := range parsedFeed.Items {
 		item := normalizeFeedItem(entry)
-		if item != nil {
-			items = append(items, *item)
-		} else {
+		if item == nil {
 			log.Printf("Warning: Skipping item with no GUID or link - Title: %s, Feed: %s",
 				entry.Title, url)
+			continue
 		}
+
+		items = append(items, *item)
 	}
 	result.Items = items
 
@@ -139,6 +147,13 @@ func normalizeFeedItem(entry *gofeed.Item) *FeedItem {
 
 	summary := getSummary(entry)
 
+	author := ""
+	if entry.Author != nil {
+		author = entry.Author.Name
+	} else if len(entry.Authors) > 0 && entry.Authors[0] != nil {
+		author = entry.Authors[0].Name
+	}
+
 	return &FeedItem{
 		Title:       title,
 		Link:        link,
@@ -146,7 +161,34 @@ func normalizeFeedItem(entry *gofeed.Item) *FeedItem {
 		Published:   published,
 		PublishedDT: publishedDT,
 		Summary:     summary,
+		Author:      author,
+		Categories:  entry.Categories,
+	}
+}
+
+// resolveFullContent returns the cached or freshly-extracted full article
+// body for an item whose feed has fetch_full_content enabled, falling back
+// to the feed-provided summary if extraction fails for any reason. The
+// cache write goes through writes like every other DB mutation in a poll
+// tick's worker pool.
+func resolveFullContent(item FeedItem, userAgent string, writes chan dbWriteOp) string {
+	if cached, found, err := GetCachedArticle(item.GUID); err == nil && found {
+		return cached
 	}
+
+	content, err := FetchFullArticle(item.Link, userAgent)
+	if err != nil {
+		log.Printf("Warning: failed to fetch full content for %s: %v", item.Link, err)
+		return item.Summary
+	}
+
+	if err := submitWrite(writes, func() error {
+		return CacheArticle(item.GUID, content)
+	}); err != nil {
+		log.Printf("Warning: failed to cache article %s: %v", item.GUID, err)
+	}
+
+	return content
 }
 
 func getSummary(entry *gofeed.Item) string {